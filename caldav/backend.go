@@ -0,0 +1,129 @@
+// Package caldav exposes a scraped Carroll calendar as a read-only CalDAV
+// collection, wrapping github.com/emersion/go-webdav/caldav.Backend so
+// clients like Thunderbird, Apple Calendar, or alps can subscribe to it
+// instead of re-downloading carroll.ics.
+package caldav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/emersion/go-webdav/caldav"
+	"github.com/jforseth210/CarrollCalendarParser/carrollcal"
+)
+
+const (
+	userPrincipalPath = "/dav/"
+	homeSetPath       = "/dav/carroll/"
+	calendarPath      = "/dav/carroll/"
+	calendarName      = "Carroll College Events"
+)
+
+// EventSource supplies the events a Backend serves. A *calendarStore or
+// anything else that can hand back the current scrape satisfies it.
+type EventSource interface {
+	Events() []carrollcal.Event
+}
+
+// Backend implements caldav.Backend over an EventSource. It is read-only:
+// all mutating methods return an error.
+type Backend struct {
+	Source EventSource
+}
+
+var _ caldav.Backend = (*Backend)(nil)
+
+// CurrentUserPrincipal satisfies webdav.UserPrincipalBackend. The server
+// doesn't have users, so every request is treated as the same anonymous
+// principal.
+func (b *Backend) CurrentUserPrincipal(ctx context.Context) (string, error) {
+	return userPrincipalPath, nil
+}
+
+func (b *Backend) CalendarHomeSetPath(ctx context.Context) (string, error) {
+	return homeSetPath, nil
+}
+
+func (b *Backend) ListCalendars(ctx context.Context) ([]caldav.Calendar, error) {
+	return []caldav.Calendar{b.calendar()}, nil
+}
+
+func (b *Backend) GetCalendar(ctx context.Context, path string) (*caldav.Calendar, error) {
+	if path != calendarPath {
+		return nil, caldav.NewPreconditionError(caldav.PreconditionCalendarCollectionLocationOk)
+	}
+	cal := b.calendar()
+	return &cal, nil
+}
+
+func (b *Backend) calendar() caldav.Calendar {
+	return caldav.Calendar{
+		Path:                  calendarPath,
+		Name:                  calendarName,
+		SupportedComponentSet: []string{ical.CompEvent},
+	}
+}
+
+func (b *Backend) GetCalendarObject(ctx context.Context, path string, req *caldav.CalendarCompRequest) (*caldav.CalendarObject, error) {
+	for _, event := range b.Source.Events() {
+		if eventPath(event) == path {
+			return calendarObject(event), nil
+		}
+	}
+	return nil, fmt.Errorf("caldav: no calendar object at %q", path)
+}
+
+func (b *Backend) ListCalendarObjects(ctx context.Context, path string, req *caldav.CalendarCompRequest) ([]caldav.CalendarObject, error) {
+	events := b.Source.Events()
+	objects := make([]caldav.CalendarObject, 0, len(events))
+	for _, event := range events {
+		objects = append(objects, *calendarObject(event))
+	}
+	return objects, nil
+}
+
+// QueryCalendarObjects translates a CalDAV calendar-query time-range filter
+// into the subset of scraped events that fall inside it.
+func (b *Backend) QueryCalendarObjects(ctx context.Context, path string, query *caldav.CalendarQuery) ([]caldav.CalendarObject, error) {
+	start, end := timeRange(query.CompFilter)
+
+	var objects []caldav.CalendarObject
+	for _, event := range b.Source.Events() {
+		if !start.IsZero() && event.End.Before(start) {
+			continue
+		}
+		if !end.IsZero() && event.Start.After(end) {
+			continue
+		}
+		objects = append(objects, *calendarObject(event))
+	}
+	return objects, nil
+}
+
+// timeRange finds the VEVENT-level time-range filter, if any, nested in a
+// calendar-query's top-level VCALENDAR comp-filter.
+func timeRange(filter caldav.CompFilter) (start, end time.Time) {
+	for _, comp := range filter.Comps {
+		if comp.Name == ical.CompEvent {
+			return comp.Start, comp.End
+		}
+	}
+	return filter.Start, filter.End
+}
+
+func (b *Backend) CreateCalendar(ctx context.Context, calendar *caldav.Calendar) error {
+	return errReadOnly
+}
+
+func (b *Backend) PutCalendarObject(ctx context.Context, path string, cal *ical.Calendar, opts *caldav.PutCalendarObjectOptions) (*caldav.CalendarObject, error) {
+	return nil, errReadOnly
+}
+
+func (b *Backend) DeleteCalendarObject(ctx context.Context, path string) error {
+	return errReadOnly
+}
+
+var errReadOnly = errors.New("caldav: this calendar is read-only")
@@ -0,0 +1,42 @@
+package caldav
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/url"
+
+	"github.com/emersion/go-ical"
+	"github.com/emersion/go-webdav/caldav"
+	"github.com/jforseth210/CarrollCalendarParser/carrollcal"
+)
+
+// eventPath returns the CalDAV resource path a scraped event is served at.
+func eventPath(event carrollcal.Event) string {
+	sum := sha1.Sum([]byte(event.UID))
+	return calendarPath + hex.EncodeToString(sum[:]) + ".ics"
+}
+
+// calendarObject converts a scraped event into the single-VEVENT
+// ical.Calendar a CalDAV client expects at its resource path.
+func calendarObject(event carrollcal.Event) *caldav.CalendarObject {
+	cal := ical.NewCalendar()
+	cal.Props.SetText(ical.PropProductID, "-//CarrollCalendarParser//carrollcal-server//EN")
+	cal.Props.SetText(ical.PropVersion, "2.0")
+
+	vevent := ical.NewComponent(ical.CompEvent)
+	vevent.Props.SetText(ical.PropUID, event.UID)
+	vevent.Props.SetText(ical.PropSummary, event.Summary)
+	vevent.Props.SetDateTime(ical.PropDateTimeStart, event.Start)
+	vevent.Props.SetDateTime(ical.PropDateTimeEnd, event.End)
+	vevent.Props.SetText(ical.PropLocation, event.Location)
+	vevent.Props.SetText(ical.PropDescription, event.Description)
+	if u, err := url.Parse(event.URL); err == nil {
+		vevent.Props.SetURI(ical.PropURL, u)
+	}
+	cal.Children = append(cal.Children, vevent)
+
+	return &caldav.CalendarObject{
+		Path: eventPath(event),
+		Data: cal,
+	}
+}
@@ -0,0 +1,68 @@
+package carrollcal
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache persists fetched pages to disk, keyed by a hash of their URL, so a
+// later run can issue a conditional GET instead of re-downloading them.
+type Cache struct {
+	Dir string
+}
+
+// cacheEntry is the on-disk representation of a cached response.
+type cacheEntry struct {
+	FetchedAt    time.Time `json:"fetched_at"`
+	ETag         string    `json:"etag"`
+	LastModified string    `json:"last_modified"`
+	Body         []byte    `json:"body"`
+}
+
+func (c *Cache) path(url string) string {
+	return filepath.Join(c.Dir, urlHash(url)+".json")
+}
+
+// load returns the cached entry for url, or nil if nothing is cached yet.
+func (c *Cache) load(url string) (*cacheEntry, error) {
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (c *Cache) store(url string, entry *cacheEntry) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(url), data, 0644)
+}
+
+func urlHash(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+// uidForURL derives a deterministic event UID from its source URL, so the
+// same Carroll event gets the same UID across runs instead of a fresh
+// uuid.NewString() every time.
+func uidForURL(url string) string {
+	return urlHash(url)
+}
@@ -0,0 +1,31 @@
+package carrollcal
+
+import "testing"
+
+func TestUrlHashIsDeterministic(t *testing.T) {
+	const url = "https://www.carroll.edu/news-events/events/example"
+
+	first := urlHash(url)
+	second := urlHash(url)
+	if first != second {
+		t.Fatalf("urlHash(%q) returned different values on repeated calls: %q vs %q", url, first, second)
+	}
+	if first == "" {
+		t.Fatalf("urlHash(%q) returned an empty string", url)
+	}
+}
+
+func TestUrlHashDiffersByURL(t *testing.T) {
+	a := urlHash("https://www.carroll.edu/news-events/events/one")
+	b := urlHash("https://www.carroll.edu/news-events/events/two")
+	if a == b {
+		t.Fatalf("urlHash returned the same hash for two different URLs: %q", a)
+	}
+}
+
+func TestUidForURLMatchesUrlHash(t *testing.T) {
+	const url = "https://www.carroll.edu/news-events/events/example"
+	if uid, hash := uidForURL(url), urlHash(url); uid != hash {
+		t.Fatalf("uidForURL(%q) = %q, want %q (urlHash)", url, uid, hash)
+	}
+}
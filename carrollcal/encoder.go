@@ -0,0 +1,73 @@
+package carrollcal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+)
+
+// Encoder writes a scraped calendar out in some serialization format.
+type Encoder interface {
+	Encode(w io.Writer, cal *ics.Calendar) error
+}
+
+// EncoderForFormat returns the Encoder registered for format, which must be
+// one of "ics", "json", or "csv".
+func EncoderForFormat(format string) (Encoder, error) {
+	switch format {
+	case "ics":
+		return ICSEncoder{}, nil
+	case "json":
+		return JSONEncoder{}, nil
+	case "csv":
+		return CSVEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("carrollcal: unknown format %q", format)
+	}
+}
+
+// ICSEncoder writes the calendar out verbatim as iCalendar, same as
+// Calendar.Serialize.
+type ICSEncoder struct{}
+
+func (ICSEncoder) Encode(w io.Writer, cal *ics.Calendar) error {
+	_, err := io.WriteString(w, cal.Serialize())
+	return err
+}
+
+// JSONEncoder writes the calendar's events as a JSON array of Event.
+type JSONEncoder struct{}
+
+func (JSONEncoder) Encode(w io.Writer, cal *ics.Calendar) error {
+	return json.NewEncoder(w).Encode(Events(cal))
+}
+
+// CSVEncoder writes the calendar's events as CSV, one row per event.
+type CSVEncoder struct{}
+
+func (CSVEncoder) Encode(w io.Writer, cal *ics.Calendar) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"uid", "summary", "start", "end", "location", "url", "description"}); err != nil {
+		return err
+	}
+	for _, event := range Events(cal) {
+		row := []string{
+			event.UID,
+			event.Summary,
+			event.Start.Format(time.RFC3339),
+			event.End.Format(time.RFC3339),
+			event.Location,
+			event.URL,
+			event.Description,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,84 @@
+package carrollcal
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+)
+
+func testCalendar() *ics.Calendar {
+	cal := ics.NewCalendar()
+	cal.AddVEvent(newTestEvent("event-1", "Test Event", time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC)))
+	return cal
+}
+
+func TestEncoderForFormat(t *testing.T) {
+	for _, format := range []string{"ics", "json", "csv"} {
+		if _, err := EncoderForFormat(format); err != nil {
+			t.Errorf("EncoderForFormat(%q) returned an error: %v", format, err)
+		}
+	}
+
+	if _, err := EncoderForFormat("xml"); err == nil {
+		t.Error("EncoderForFormat(\"xml\") did not return an error for an unknown format")
+	}
+}
+
+func TestICSEncoderRoundTrips(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (ICSEncoder{}).Encode(&buf, testCalendar()); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	parsed, err := ics.ParseCalendar(&buf)
+	if err != nil {
+		t.Fatalf("ParseCalendar: %v", err)
+	}
+	if len(parsed.Events()) != 1 {
+		t.Fatalf("got %d events after round-trip, want 1", len(parsed.Events()))
+	}
+}
+
+func TestJSONEncoderWritesEventArray(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (JSONEncoder{}).Encode(&buf, testCalendar()); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	var events []Event
+	if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if events[0].UID != "event-1" || events[0].Summary != "Test Event" {
+		t.Fatalf("got event %+v, want UID=event-1 Summary=\"Test Event\"", events[0])
+	}
+}
+
+func TestCSVEncoderWritesHeaderAndRows(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (CSVEncoder{}).Encode(&buf, testCalendar()); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2 (header + 1 event)", len(rows))
+	}
+	if rows[0][0] != "uid" {
+		t.Fatalf("got header %v, want it to start with \"uid\"", rows[0])
+	}
+	if rows[1][0] != "event-1" {
+		t.Fatalf("got row %v, want it to start with \"event-1\"", rows[1])
+	}
+}
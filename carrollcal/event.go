@@ -0,0 +1,51 @@
+package carrollcal
+
+import (
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+)
+
+// Event is a flattened, JSON-friendly view of a scraped VEvent.
+type Event struct {
+	UID         string    `json:"uid"`
+	Summary     string    `json:"summary"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Location    string    `json:"location"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+}
+
+// Events flattens every VEvent in cal into the Event struct used by the
+// JSON API.
+func Events(cal *ics.Calendar) []Event {
+	vevents := cal.Events()
+	events := make([]Event, 0, len(vevents))
+	for _, vevent := range vevents {
+		events = append(events, eventFromVEvent(vevent))
+	}
+	return events
+}
+
+func eventFromVEvent(vevent *ics.VEvent) Event {
+	start, _ := vevent.GetStartAt()
+	end, _ := vevent.GetEndAt()
+	return Event{
+		UID:         propertyValue(vevent, ics.ComponentPropertyUniqueId),
+		Summary:     propertyValue(vevent, ics.ComponentPropertySummary),
+		Start:       start,
+		End:         end,
+		Location:    propertyValue(vevent, ics.ComponentPropertyLocation),
+		URL:         propertyValue(vevent, ics.ComponentPropertyUrl),
+		Description: propertyValue(vevent, ics.ComponentPropertyDescription),
+	}
+}
+
+func propertyValue(vevent *ics.VEvent, property ics.ComponentProperty) string {
+	prop := vevent.GetProperty(property)
+	if prop == nil {
+		return ""
+	}
+	return prop.Value
+}
@@ -0,0 +1,104 @@
+package carrollcal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// maxAttempts is how many times Fetcher.Get will try a request before
+// giving up.
+const maxAttempts = 3
+
+// Fetcher performs rate-limited, retrying HTTP GETs against carroll.edu.
+// It's shared by every worker in a Scraper's pool so they all respect the
+// same rate limit.
+type Fetcher struct {
+	Client      *http.Client
+	Concurrency int
+	UserAgent   string
+
+	limiter *rate.Limiter
+}
+
+// NewFetcher returns a Fetcher that runs up to concurrency requests at
+// once, throttled to requestsPerSecond, identifying itself with userAgent
+// if non-empty.
+func NewFetcher(client *http.Client, concurrency int, requestsPerSecond float64, userAgent string) *Fetcher {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Fetcher{
+		Client:      client,
+		Concurrency: concurrency,
+		UserAgent:   userAgent,
+		limiter:     rate.NewLimiter(rate.Limit(requestsPerSecond), 1),
+	}
+}
+
+// Get fetches url, trying up to maxAttempts times in all, with exponential
+// backoff between attempts, when the server returns a 5xx response or the
+// request fails outright (a timeout, a refused connection, and so on).
+func (f *Fetcher) Get(url string) (*http.Response, error) {
+	return f.get(url, "", "")
+}
+
+// GetConditional fetches url, sending If-None-Match/If-Modified-Since
+// headers when etag/lastModified are non-empty. The caller should check
+// resp.StatusCode == http.StatusNotModified before reading the body.
+func (f *Fetcher) GetConditional(url, etag, lastModified string) (*http.Response, error) {
+	return f.get(url, etag, lastModified)
+}
+
+func (f *Fetcher) get(url, etag, lastModified string) (*http.Response, error) {
+	var lastErr error
+	backoff := 500 * time.Millisecond
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err := f.limiter.Wait(context.Background()); err != nil {
+			return nil, err
+		}
+
+		resp, err := f.do(url, etag, lastModified)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+
+		if attempt < maxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	return nil, fmt.Errorf("carrollcal: giving up on %s after %d attempts: %w", url, maxAttempts, lastErr)
+}
+
+func (f *Fetcher) do(url, etag, lastModified string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 500 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,147 @@
+package carrollcal
+
+import (
+	"fmt"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+	"github.com/teambition/rrule-go"
+)
+
+// FilterCalendar returns a new calendar containing only the events from cal
+// that occur within [from, to].
+//
+// Non-recurring events are kept verbatim when their span overlaps the
+// window. Recurring events (an RRULE, with optional EXDATE/RDATE) are
+// expanded into one concrete VEVENT per occurrence inside the window, each
+// with its own RECURRENCE-ID and a UID derived from the master's UID and
+// the occurrence's start time. The master event is kept alongside its
+// expansions as long as it still has occurrences after `to`, so a
+// subscriber that understands RRULE still gets the live recurrence rule.
+func FilterCalendar(cal *ics.Calendar, from, to time.Time) *ics.Calendar {
+	filtered := ics.NewCalendar()
+	filtered.SetMethod(ics.MethodRequest)
+
+	for _, event := range cal.Events() {
+		if event.GetProperty(ics.ComponentPropertyRrule) == nil {
+			if eventIntersects(event, from, to) {
+				filtered.AddVEvent(event)
+			}
+			continue
+		}
+
+		instances, keepMaster, err := expandRecurringEvent(event, from, to)
+		if err != nil {
+			// Couldn't parse the RRULE; fall back to treating it as a
+			// plain event so it isn't silently dropped.
+			if eventIntersects(event, from, to) {
+				filtered.AddVEvent(event)
+			}
+			continue
+		}
+
+		if keepMaster {
+			filtered.AddVEvent(event)
+		}
+		for _, instance := range instances {
+			filtered.AddVEvent(instance)
+		}
+	}
+
+	return filtered
+}
+
+func eventIntersects(event *ics.VEvent, from, to time.Time) bool {
+	start, err := event.GetStartAt()
+	if err != nil {
+		return false
+	}
+	end, err := event.GetEndAt()
+	if err != nil {
+		end = start
+	}
+	return !end.Before(from) && !start.After(to)
+}
+
+// expandRecurringEvent expands event's RRULE into concrete occurrences
+// within [from, to], honoring EXDATE/RDATE. keepMaster reports whether the
+// rule still has occurrences after `to`.
+func expandRecurringEvent(event *ics.VEvent, from, to time.Time) (instances []*ics.VEvent, keepMaster bool, err error) {
+	rruleProp := event.GetProperty(ics.ComponentPropertyRrule)
+
+	start, err := event.GetStartAt()
+	if err != nil {
+		return nil, false, err
+	}
+	end, err := event.GetEndAt()
+	if err != nil {
+		end = start
+	}
+	duration := end.Sub(start)
+
+	rule, err := rrule.StrToRRule(rruleProp.Value)
+	if err != nil {
+		return nil, false, err
+	}
+	rule.DTStart(start)
+
+	exdates, err := event.GetExDates()
+	if err != nil {
+		exdates = nil
+	}
+	rdates, err := event.GetRDates()
+	if err != nil {
+		rdates = nil
+	}
+
+	occurrences := rule.Between(from, to, true)
+	for _, rdate := range rdates {
+		if !rdate.Before(from) && !rdate.After(to) {
+			occurrences = appendUnique(occurrences, rdate)
+		}
+	}
+
+	uid := event.Id()
+	for _, occStart := range occurrences {
+		if containsTime(exdates, occStart) {
+			continue
+		}
+		instances = append(instances, recurrenceInstance(event, uid, occStart, occStart.Add(duration)))
+	}
+
+	keepMaster = !rule.After(to, false).IsZero()
+	return instances, keepMaster, nil
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, candidate := range times {
+		if candidate.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(times []time.Time, t time.Time) []time.Time {
+	if containsTime(times, t) {
+		return times
+	}
+	return append(times, t)
+}
+
+// recurrenceInstance builds the concrete VEvent for one occurrence of a
+// recurring master event.
+func recurrenceInstance(master *ics.VEvent, masterUID string, start, end time.Time) *ics.VEvent {
+	instance := ics.NewEvent(fmt.Sprintf("%s-%d", masterUID, start.Unix()))
+	instance.SetSummary(propertyValue(master, ics.ComponentPropertySummary))
+	instance.SetLocation(propertyValue(master, ics.ComponentPropertyLocation))
+	instance.SetDescription(propertyValue(master, ics.ComponentPropertyDescription))
+	instance.SetURL(propertyValue(master, ics.ComponentPropertyUrl))
+	instance.SetStartAt(start)
+	instance.SetEndAt(end)
+	instance.SetProperty(ics.ComponentPropertyRecurrenceId, start.UTC().Format("20060102T150405Z"))
+	instance.SetCreatedTime(time.Now())
+	instance.SetDtStampTime(time.Now())
+	instance.SetModifiedAt(time.Now())
+	return instance
+}
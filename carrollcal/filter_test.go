@@ -0,0 +1,103 @@
+package carrollcal
+
+import (
+	"testing"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+)
+
+func newTestEvent(uid, summary string, start, end time.Time) *ics.VEvent {
+	event := ics.NewEvent(uid)
+	event.SetSummary(summary)
+	event.SetStartAt(start)
+	event.SetEndAt(end)
+	return event
+}
+
+func TestFilterCalendarKeepsNonRecurringEventsInRange(t *testing.T) {
+	cal := ics.NewCalendar()
+	inRange := newTestEvent("in-range", "In range", time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC), time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC))
+	outOfRange := newTestEvent("out-of-range", "Out of range", time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))
+	cal.AddVEvent(inRange)
+	cal.AddVEvent(outOfRange)
+
+	filtered := FilterCalendar(cal, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC))
+
+	events := filtered.Events()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if events[0].Id() != "in-range" {
+		t.Fatalf("got event %q, want %q", events[0].Id(), "in-range")
+	}
+}
+
+func TestFilterCalendarExpandsWeeklyRRule(t *testing.T) {
+	cal := ics.NewCalendar()
+	start := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC) // Monday
+	master := newTestEvent("weekly-standup", "Standup", start, start.Add(30*time.Minute))
+	master.SetProperty(ics.ComponentPropertyRrule, "FREQ=WEEKLY;COUNT=4")
+	master.SetProperty(ics.ComponentPropertyExdate, start.AddDate(0, 0, 7).UTC().Format("20060102T150405Z"))
+	cal.AddVEvent(master)
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	filtered := FilterCalendar(cal, from, to)
+
+	events := filtered.Events()
+	// 4 occurrences (Jan 5, 12, 19, 26), one excluded by EXDATE (Jan 12) ->
+	// 3 instances, no master since the rule has no occurrences after `to`.
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %v", len(events), eventStarts(t, events))
+	}
+
+	for _, event := range events {
+		if event.GetProperty(ics.ComponentPropertyRrule) != nil {
+			t.Fatalf("expanded instance %q unexpectedly still carries an RRULE", event.Id())
+		}
+		if event.GetProperty(ics.ComponentPropertyRecurrenceId) == nil {
+			t.Fatalf("expanded instance %q is missing RECURRENCE-ID", event.Id())
+		}
+	}
+
+	occStart, _ := events[0].GetStartAt()
+	if !occStart.Equal(start) {
+		t.Fatalf("first occurrence starts at %v, want %v", occStart, start)
+	}
+}
+
+func TestFilterCalendarKeepsMasterWithFutureOccurrences(t *testing.T) {
+	cal := ics.NewCalendar()
+	start := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	master := newTestEvent("ongoing", "Ongoing weekly", start, start.Add(30*time.Minute))
+	master.SetProperty(ics.ComponentPropertyRrule, "FREQ=WEEKLY;COUNT=52")
+	cal.AddVEvent(master)
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	filtered := FilterCalendar(cal, from, to)
+
+	var foundMaster bool
+	for _, event := range filtered.Events() {
+		if event.Id() == "ongoing" && event.GetProperty(ics.ComponentPropertyRrule) != nil {
+			foundMaster = true
+		}
+	}
+	if !foundMaster {
+		t.Fatalf("master event with occurrences after `to` was dropped")
+	}
+}
+
+func eventStarts(t *testing.T, events []*ics.VEvent) []time.Time {
+	t.Helper()
+	starts := make([]time.Time, len(events))
+	for i, event := range events {
+		start, err := event.GetStartAt()
+		if err != nil {
+			t.Fatalf("GetStartAt: %v", err)
+		}
+		starts[i] = start
+	}
+	return starts
+}
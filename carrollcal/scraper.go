@@ -0,0 +1,426 @@
+// Package carrollcal scrapes the Carroll College events calendar
+// (https://www.carroll.edu/news-events/events) and builds an ics.Calendar
+// from it.
+package carrollcal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	ics "github.com/arran4/golang-ical"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+const monthsURL = "http://www.carroll.edu/news-events/events/"
+
+// defaultConcurrency, defaultRequestsPerSecond are the pool size and rate
+// limit a Scraper uses when constructed with NewScraper.
+const (
+	defaultConcurrency       = 4
+	defaultRequestsPerSecond = 2
+)
+
+// Scraper fetches and parses pages from the Carroll College events
+// calendar. The zero value is not usable; construct one with NewScraper.
+type Scraper struct {
+	// Fetcher performs the underlying HTTP requests, pooling and
+	// rate-limiting them. It may be replaced to tune concurrency, the
+	// request rate, or the User-Agent sent.
+	Fetcher *Fetcher
+
+	// Cache, if set, persists fetched pages to disk keyed by URL so
+	// subsequent runs issue conditional GETs instead of re-downloading
+	// pages that haven't changed.
+	Cache *Cache
+
+	// OnProgress, if set, is called with the calendar built so far after
+	// each month finishes scraping, so a caller can save partial progress
+	// (e.g. on interrupt) without waiting for the whole range to finish.
+	OnProgress func(*ics.Calendar)
+}
+
+// NewScraper returns a Scraper with a Fetcher using sensible defaults: a
+// worker pool of 4 and a rate limit of 2 requests/second.
+func NewScraper() *Scraper {
+	return &Scraper{Fetcher: NewFetcher(http.DefaultClient, defaultConcurrency, defaultRequestsPerSecond, "")}
+}
+
+// ScrapeError reports that a single event page failed to scrape. It
+// carries the offending URL so callers can tell which event was skipped
+// without having to parse a log line.
+type ScrapeError struct {
+	URL string
+	Err error
+}
+
+func (e *ScrapeError) Error() string {
+	return fmt.Sprintf("carrollcal: failed to scrape %s: %v", e.URL, e.Err)
+}
+
+func (e *ScrapeError) Unwrap() error {
+	return e.Err
+}
+
+// ScrapeRange scrapes every event listed on the Carroll calendar between
+// the months containing start and end (inclusive) and returns them as an
+// ics.Calendar. Individual events that fail to scrape are skipped rather
+// than aborting the whole range.
+func (s *Scraper) ScrapeRange(start, end time.Time) (*ics.Calendar, error) {
+	return s.scrapeRange(start, end, func(links []string) []*ics.VEvent {
+		return s.scrapeLinks(links, s.ScrapeEvent)
+	})
+}
+
+// ScrapeRangeIncremental behaves like ScrapeRange, but reuses events from
+// previous whose source page hasn't changed since it was cached (see
+// Cache), only re-scraping pages that are new or have changed. previous
+// may be nil, in which case every event is scraped fresh. With no Cache set,
+// every page counts as changed, so every event is scraped fresh as well.
+func (s *Scraper) ScrapeRangeIncremental(start, end time.Time, previous *ics.Calendar) (*ics.Calendar, error) {
+	previousByUID := map[string]*ics.VEvent{}
+	if previous != nil {
+		for _, event := range previous.Events() {
+			previousByUID[event.Id()] = event
+		}
+	}
+
+	return s.scrapeRange(start, end, func(links []string) []*ics.VEvent {
+		return s.scrapeLinks(links, func(link string) (*ics.VEvent, error) {
+			return s.scrapeEventIncremental(link, previousByUID)
+		})
+	})
+}
+
+func (s *Scraper) scrapeRange(start, end time.Time, scrapeMonth func(links []string) []*ics.VEvent) (*ics.Calendar, error) {
+	cal := ics.NewCalendar()
+	cal.SetMethod(ics.MethodRequest)
+
+	for _, month := range generateMonthList(start, end) {
+		// Load the month's calendar page
+		doc, _, err := s.loadPage(monthsURL + month)
+		if err != nil {
+			return nil, err
+		}
+		// Find all the links to events
+		links := getEventLinksFromHTML(doc)
+
+		for _, event := range scrapeMonth(links) {
+			cal.AddVEvent(event)
+		}
+
+		if s.OnProgress != nil {
+			s.OnProgress(cal)
+		}
+	}
+
+	return cal, nil
+}
+
+// scrapeLinks runs scrape over links through the Fetcher's worker pool,
+// running up to Fetcher.Concurrency fetches in parallel. A link that
+// fails to scrape is logged as a ScrapeError and skipped rather than
+// aborting the rest of the month.
+func (s *Scraper) scrapeLinks(links []string, scrape func(string) (*ics.VEvent, error)) []*ics.VEvent {
+	jobs := make(chan string)
+	results := make(chan *ics.VEvent)
+
+	var workers sync.WaitGroup
+	for i := 0; i < s.Fetcher.Concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for link := range jobs {
+				event, err := scrape(link)
+				if err != nil {
+					log.Println(&ScrapeError{URL: link, Err: err})
+					continue
+				}
+				results <- event
+			}
+		}()
+	}
+
+	go func() {
+		for _, link := range links {
+			jobs <- link
+		}
+		close(jobs)
+	}()
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	events := make([]*ics.VEvent, 0, len(links))
+	for event := range results {
+		events = append(events, event)
+	}
+	return events
+}
+
+// ScrapeEvent loads a single Carroll event page and parses it into a
+// standalone ics.VEvent. The returned event is not attached to any
+// calendar.
+func (s *Scraper) ScrapeEvent(url string) (*ics.VEvent, error) {
+	doc, _, err := s.loadPage(url)
+	if err != nil {
+		return nil, err
+	}
+	return parseEvent(doc, uidForURL(url), url)
+}
+
+// scrapeEventIncremental is like ScrapeEvent, but if the page is unchanged
+// since the last run (per Cache) and a previous event for it exists, the
+// previous event is reused instead of being re-parsed and re-stamped.
+func (s *Scraper) scrapeEventIncremental(url string, previous map[string]*ics.VEvent) (*ics.VEvent, error) {
+	doc, changed, err := s.loadPage(url)
+	if err != nil {
+		return nil, err
+	}
+
+	uid := uidForURL(url)
+	if !changed {
+		if event, ok := previous[uid]; ok {
+			return event, nil
+		}
+	}
+
+	return parseEvent(doc, uid, url)
+}
+
+func parseEvent(doc *goquery.Document, uid, url string) (*ics.VEvent, error) {
+	// Find the title of the event
+	title := parseTitle(doc)
+
+	// Find the start time of the event
+	startTime, err := parseStartTime(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the end time of the event
+	endTime, err := parseEndTime(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the event location
+	location := parseLocation(doc)
+
+	// Find the event description
+	description := parseDescription(doc)
+
+	return newEvent(uid, title, *startTime, *endTime, location, description, url), nil
+}
+
+// Loads a page from a URL and parses the HTML. changed reports whether the
+// page had to be freshly fetched; it's always true when Cache is nil.
+func (s *Scraper) loadPage(url string) (doc *goquery.Document, changed bool, err error) {
+	body, changed, err := s.fetchBody(url)
+	if err != nil {
+		return nil, false, err
+	}
+
+	doc, err = goquery.NewDocumentFromReader(bytes.NewReader(body))
+	return doc, changed, err
+}
+
+// fetchBody fetches url's body, consulting and updating Cache if one is
+// configured. changed is false only when Cache returns a fresh, unchanged
+// cached copy via a conditional GET.
+func (s *Scraper) fetchBody(url string) (body []byte, changed bool, err error) {
+	if s.Cache == nil {
+		resp, err := s.Fetcher.Get(url)
+		if err != nil {
+			return nil, false, err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return body, true, err
+	}
+
+	cached, err := s.Cache.load(url)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var etag, lastModified string
+	if cached != nil {
+		etag, lastModified = cached.ETag, cached.LastModified
+	}
+
+	resp, err := s.Fetcher.GetConditional(url, etag, lastModified)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.Body, false, nil
+	}
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = s.Cache.store(url, &cacheEntry{
+		FetchedAt:    time.Now(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Body:         body,
+	})
+	return body, true, err
+}
+
+// Get the links to events from a Carroll calendar page
+func getEventLinksFromHTML(doc *goquery.Document) []string {
+	// Find all the anchor tags in the month table
+	anchorTags := doc.Find("table a")
+
+	var links []string
+
+	// Iterate through the event links
+	anchorTags.Each(func(i int, anchorTag *goquery.Selection) {
+		// Get the href attribute of the anchor tag
+		relativeLink, exists := anchorTag.Attr("href")
+		if !exists {
+			return
+		}
+
+		// Make sure it's a link to a carroll event
+		if !strings.Contains(relativeLink, "/news-events/events") {
+			return
+		}
+
+		// Make sure it hasn't been added already
+		// (There are multiple links to multi-day events)
+		for _, existingLink := range links {
+			if existingLink == "https://www.carroll.edu"+relativeLink {
+				return
+			}
+		}
+
+		// Add the link to the list
+		links = append(links, "https://www.carroll.edu"+relativeLink)
+	})
+	// Return the list of links
+	return links
+}
+
+// Get a title from from a Carroll event page
+func parseTitle(doc *goquery.Document) string {
+	// Get the title
+	title := doc.Find(".hero__title").Text()
+	// Get rid of all caps
+	title = cases.Title(language.English).String(title)
+	// Get rid of extra whitespace
+	title = strings.TrimSpace(title)
+	return title
+}
+
+// Get the start time from a Carroll event page
+func parseStartTime(doc *goquery.Document) (*time.Time, error) {
+	// Find the date area
+	dates := doc.Find(".event__date").Find("time")
+
+	// Find the start date
+	var unixString string
+	var exists bool
+	dates.Each(func(i int, date *goquery.Selection) {
+		if i == 0 {
+			unixString, exists = date.Attr("datetime")
+		}
+	})
+	// Parse
+	if !exists {
+		return nil, errors.New("No start time found")
+	}
+	dateInt, err := strconv.ParseInt(unixString, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	tm := time.Unix(dateInt, 0).Local()
+	return &tm, nil
+}
+
+// Get the end time from a Carroll event page
+func parseEndTime(doc *goquery.Document) (*time.Time, error) {
+	// Find the date area
+	dates := doc.Find(".event__date").Find("time")
+
+	//Find the end date
+	var unixString string
+	var exists bool
+	dates.Each(func(i int, date *goquery.Selection) {
+		unixString, exists = date.Attr("datetime")
+	})
+
+	if !exists {
+		return nil, errors.New("No end time found")
+	}
+	dateInt, err := strconv.ParseInt(unixString, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	tm := time.Unix(dateInt, 0).Local()
+	return &tm, nil
+}
+
+func parseLocation(doc *goquery.Document) string {
+	location := doc.Find(".event__location").Text()
+	location = strings.Replace(location, "Campus", "Campus\n", -1)
+	location = strings.TrimSpace(location)
+	return location
+}
+
+func parseDescription(doc *goquery.Document) string {
+	description := doc.Find(".text-content").Children().First().Text()
+	description = strings.TrimSpace(description)
+	return description
+
+}
+
+// Create a standalone ics.VEvent with the data provided
+func newEvent(uid string, summary string, start time.Time, end time.Time, location string, description string, url string) *ics.VEvent {
+	event := ics.NewEvent(uid)
+	event.SetCreatedTime(time.Now())
+	event.SetDtStampTime(time.Now())
+	event.SetModifiedAt(time.Now())
+	event.SetStartAt(start)
+	event.SetEndAt(end)
+	event.SetSummary(summary)
+	event.SetLocation(location)
+	event.SetDescription(description)
+	event.SetURL(url)
+	return event
+}
+
+// This function generates a list of month strings from the start and end
+// dates, in the YYYYMM format Carroll's calendar URLs expect.
+// It's AI generated and works by adding a month to the start month until
+// it's greater than the end date.
+func generateMonthList(start, end time.Time) []string {
+	var monthList []string
+
+	startDate := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	endDate := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location())
+
+	// Generate month list
+	for !startDate.After(endDate) {
+		monthList = append(monthList, startDate.Format("200601"))
+		startDate = startDate.AddDate(0, 1, 0)
+	}
+
+	return monthList
+}
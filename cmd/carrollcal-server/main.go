@@ -0,0 +1,105 @@
+// Command carrollcal-server serves the scraped Carroll College calendar
+// over HTTP, refreshing it on a timer instead of requiring a fresh
+// invocation of the CLI for every update.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+	webdavcaldav "github.com/emersion/go-webdav/caldav"
+	"github.com/jforseth210/CarrollCalendarParser/caldav"
+	"github.com/jforseth210/CarrollCalendarParser/carrollcal"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	refresh := flag.Duration("refresh", 6*time.Hour, "how often to re-scrape the Carroll calendar")
+	monthsBehind := flag.Int("months-behind", 0, "number of months before the current month to include")
+	monthsAhead := flag.Int("months-ahead", 6, "number of months after the current month to include")
+	concurrency := flag.Int("concurrency", 4, "number of event pages to fetch in parallel")
+	requestsPerSecond := flag.Float64("rate", 2, "max requests per second to send to carroll.edu")
+	userAgent := flag.String("user-agent", "", "User-Agent header to send with requests")
+	flag.Parse()
+
+	scraper := &carrollcal.Scraper{
+		Fetcher: carrollcal.NewFetcher(http.DefaultClient, *concurrency, *requestsPerSecond, *userAgent),
+	}
+	store := &calendarStore{scraper: scraper}
+	if err := store.refresh(*monthsBehind, *monthsAhead); err != nil {
+		log.Fatal(err)
+	}
+	go store.refreshLoop(*refresh, *monthsBehind, *monthsAhead)
+
+	http.HandleFunc("/carroll.ics", store.serveICS)
+	http.HandleFunc("/events.json", store.serveJSON)
+
+	davHandler := &webdavcaldav.Handler{
+		Backend: &caldav.Backend{Source: store},
+		Prefix:  "/dav/",
+	}
+	http.Handle("/dav/", davHandler)
+
+	log.Printf("Listening on %s (refreshing every %s)", *addr, *refresh)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// calendarStore holds the most recently scraped calendar in memory so
+// requests don't each trigger a fresh scrape of carroll.edu.
+type calendarStore struct {
+	scraper *carrollcal.Scraper
+
+	mu  sync.RWMutex
+	cal *ics.Calendar
+}
+
+func (s *calendarStore) refresh(monthsBehind, monthsAhead int) error {
+	now := time.Now()
+	start := now.AddDate(0, -monthsBehind, 0)
+	end := now.AddDate(0, monthsAhead, 0)
+
+	cal, err := s.scraper.ScrapeRange(start, end)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.cal = cal
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *calendarStore) refreshLoop(interval time.Duration, monthsBehind, monthsAhead int) {
+	for range time.Tick(interval) {
+		if err := s.refresh(monthsBehind, monthsAhead); err != nil {
+			log.Println("Error refreshing calendar:", err)
+		}
+	}
+}
+
+func (s *calendarStore) calendar() *ics.Calendar {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cal
+}
+
+// Events satisfies caldav.EventSource, letting the CalDAV backend reuse
+// this cache instead of re-scraping carroll.edu for every REPORT request.
+func (s *calendarStore) Events() []carrollcal.Event {
+	return carrollcal.Events(s.calendar())
+}
+
+func (s *calendarStore) serveICS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/calendar")
+	w.Write([]byte(s.calendar().Serialize()))
+}
+
+func (s *calendarStore) serveJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(carrollcal.Events(s.calendar()))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+	"github.com/jforseth210/CarrollCalendarParser/carrollcal"
+)
+
+// runFilter implements the `carrollcal filter` subcommand: it narrows an
+// existing ics file down to events occurring within a date range, expanding
+// recurring events into concrete occurrences along the way.
+func runFilter(args []string) {
+	fs := flag.NewFlagSet("carrollcal filter", flag.ExitOnError)
+	from := fs.String("from", "", "start of the date range to keep, in the format YYYY-MM-DD")
+	to := fs.String("to", "", "end of the date range to keep, in the format YYYY-MM-DD")
+	explode := fs.Bool("explode", false, "drop recurring masters and keep only their expanded occurrences")
+	fs.Parse(args)
+
+	if *from == "" || *to == "" {
+		println("Please provide both --from and --to in the format YYYY-MM-DD")
+		os.Exit(1)
+	}
+	fromTime, err := time.Parse("2006-01-02", *from)
+	if err != nil {
+		println("Please provide --from in the format YYYY-MM-DD")
+		os.Exit(1)
+	}
+	toTime, err := time.Parse("2006-01-02", *to)
+	if err != nil {
+		println("Please provide --to in the format YYYY-MM-DD")
+		os.Exit(1)
+	}
+
+	cal, err := readCalendar(fs.Args())
+	if err != nil {
+		println(err.Error())
+		os.Exit(1)
+	}
+
+	filtered := carrollcal.FilterCalendar(cal, fromTime, toTime)
+	if *explode {
+		dropMasters(filtered)
+	}
+
+	os.Stdout.WriteString(filtered.Serialize())
+}
+
+// readCalendar reads and parses an ics calendar from the positional path
+// argument, or from stdin if none was given.
+func readCalendar(args []string) (*ics.Calendar, error) {
+	var r io.Reader = os.Stdin
+	if len(args) > 0 {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return ics.ParseCalendar(r)
+}
+
+// dropMasters removes recurring master events (those carrying an RRULE)
+// from cal, leaving only their already-expanded occurrences.
+func dropMasters(cal *ics.Calendar) {
+	for _, event := range cal.Events() {
+		if event.GetProperty(ics.ComponentPropertyRrule) != nil {
+			cal.RemoveEvent(event.Id())
+		}
+	}
+}
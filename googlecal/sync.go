@@ -0,0 +1,145 @@
+// Package googlecal diff-syncs scraped carrollcal events into a personal
+// Google Calendar, matching events by the deterministic UID carrollcal
+// derives from each event's source URL (see carrollcal.Cache).
+package googlecal
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/jforseth210/CarrollCalendarParser/carrollcal"
+	calendar "google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+// Syncer diff-syncs carrollcal events into a single Google Calendar.
+type Syncer struct {
+	Service    *calendar.Service
+	CalendarID string
+}
+
+// NewSyncer authenticates with the OAuth or service-account credentials at
+// credentialsFile and returns a Syncer for the calendar identified by
+// calendarID.
+func NewSyncer(ctx context.Context, credentialsFile, calendarID string) (*Syncer, error) {
+	service, err := calendar.NewService(ctx, option.WithCredentialsFile(credentialsFile))
+	if err != nil {
+		return nil, fmt.Errorf("googlecal: %w", err)
+	}
+	return &Syncer{Service: service, CalendarID: calendarID}, nil
+}
+
+// Sync reconciles the Google Calendar so it contains exactly events:
+// scraped events with no matching Google event are inserted, matching
+// events whose title/location/time/description changed are patched, and
+// Google events carrying a carrollcal UID that's no longer in events are
+// deleted. from and to bound the window of existing events considered, and
+// must cover the full range events were scraped from or already-synced
+// events just outside the boundary will look unmatched and be
+// re-imported as duplicates.
+func (s *Syncer) Sync(ctx context.Context, events []carrollcal.Event, from, to time.Time) error {
+	existing, err := s.listExisting(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(events))
+	for _, event := range events {
+		seen[event.UID] = true
+
+		current, ok := existing[event.UID]
+		if !ok {
+			// Events.Insert ignores a caller-supplied ICalUID and assigns
+			// its own; Events.Import is the only method that honors it, and
+			// that's what listExisting needs to match this event back up on
+			// the next sync.
+			if _, err := s.Service.Events.Import(s.CalendarID, toGoogleEvent(event)).Context(ctx).Do(); err != nil {
+				return fmt.Errorf("googlecal: import %s: %w", event.UID, err)
+			}
+			continue
+		}
+
+		if !googleEventMatches(current, event) {
+			if _, err := s.Service.Events.Update(s.CalendarID, current.Id, toGoogleEvent(event)).Context(ctx).Do(); err != nil {
+				return fmt.Errorf("googlecal: update %s: %w", event.UID, err)
+			}
+		}
+	}
+
+	for uid, current := range existing {
+		if seen[uid] {
+			continue
+		}
+		if err := s.Service.Events.Delete(s.CalendarID, current.Id).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("googlecal: delete %s: %w", uid, err)
+		}
+	}
+
+	return nil
+}
+
+// listExisting returns every event carrollcal previously wrote into the
+// calendar within [from, to], keyed by its carrollcal UID (the event's
+// ICalUID). Google events without a carrollcal UID are left untouched by
+// Sync.
+func (s *Syncer) listExisting(ctx context.Context, from, to time.Time) (map[string]*calendar.Event, error) {
+	existing := make(map[string]*calendar.Event)
+
+	pageToken := ""
+	for {
+		call := s.Service.Events.List(s.CalendarID).
+			SingleEvents(true).
+			TimeMin(from.Format(time.RFC3339)).
+			TimeMax(to.Format(time.RFC3339)).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		page, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("googlecal: list events: %w", err)
+		}
+		for _, event := range page.Items {
+			// Google gives every event an ICalUID of its own, so a non-empty
+			// one doesn't mark an event as carrollcal's. carrollcal UIDs are
+			// the hex SHA-1 of the event's source URL.
+			if len(event.ICalUID) != 2*sha1.Size {
+				continue
+			}
+			if _, err := hex.DecodeString(event.ICalUID); err != nil {
+				continue
+			}
+			existing[event.ICalUID] = event
+		}
+
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	return existing, nil
+}
+
+func toGoogleEvent(event carrollcal.Event) *calendar.Event {
+	return &calendar.Event{
+		ICalUID:     event.UID,
+		Summary:     event.Summary,
+		Location:    event.Location,
+		Description: event.Description,
+		Start:       &calendar.EventDateTime{DateTime: event.Start.Format(time.RFC3339)},
+		End:         &calendar.EventDateTime{DateTime: event.End.Format(time.RFC3339)},
+	}
+}
+
+func googleEventMatches(current *calendar.Event, event carrollcal.Event) bool {
+	return current.Summary == event.Summary &&
+		current.Location == event.Location &&
+		current.Description == event.Description &&
+		current.Start != nil && current.Start.DateTime == event.Start.Format(time.RFC3339) &&
+		current.End != nil && current.End.DateTime == event.End.Format(time.RFC3339)
+}
@@ -0,0 +1,78 @@
+package googlecal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jforseth210/CarrollCalendarParser/carrollcal"
+	calendar "google.golang.org/api/calendar/v3"
+)
+
+func testEvent() carrollcal.Event {
+	return carrollcal.Event{
+		UID:         "abc123",
+		Summary:     "Test Event",
+		Location:    "Campus Center",
+		Description: "A description",
+		Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC),
+		End:         time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestToGoogleEventCarriesICalUID(t *testing.T) {
+	event := testEvent()
+	got := toGoogleEvent(event)
+
+	if got.ICalUID != event.UID {
+		t.Errorf("ICalUID = %q, want %q", got.ICalUID, event.UID)
+	}
+	if got.Summary != event.Summary {
+		t.Errorf("Summary = %q, want %q", got.Summary, event.Summary)
+	}
+	if got.Start.DateTime != event.Start.Format(time.RFC3339) {
+		t.Errorf("Start.DateTime = %q, want %q", got.Start.DateTime, event.Start.Format(time.RFC3339))
+	}
+	if got.End.DateTime != event.End.Format(time.RFC3339) {
+		t.Errorf("End.DateTime = %q, want %q", got.End.DateTime, event.End.Format(time.RFC3339))
+	}
+}
+
+func TestGoogleEventMatchesIdenticalEvent(t *testing.T) {
+	event := testEvent()
+	current := toGoogleEvent(event)
+	current.Id = "google-generated-id"
+
+	if !googleEventMatches(current, event) {
+		t.Error("googleEventMatches returned false for an event round-tripped from toGoogleEvent")
+	}
+}
+
+func TestGoogleEventMatchesDetectsChanges(t *testing.T) {
+	event := testEvent()
+	base := toGoogleEvent(event)
+	base.Id = "google-generated-id"
+
+	tests := []struct {
+		name   string
+		mutate func(*calendar.Event)
+	}{
+		{"summary changed", func(e *calendar.Event) { e.Summary = "Different title" }},
+		{"location changed", func(e *calendar.Event) { e.Location = "Different room" }},
+		{"description changed", func(e *calendar.Event) { e.Description = "Different description" }},
+		{"start changed", func(e *calendar.Event) { e.Start.DateTime = event.Start.Add(time.Hour).Format(time.RFC3339) }},
+		{"end changed", func(e *calendar.Event) { e.End.DateTime = event.End.Add(time.Hour).Format(time.RFC3339) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := *base
+			startCopy, endCopy := *base.Start, *base.End
+			current.Start, current.End = &startCopy, &endCopy
+			tt.mutate(&current)
+
+			if googleEventMatches(&current, event) {
+				t.Errorf("googleEventMatches returned true after %s", tt.name)
+			}
+		})
+	}
+}
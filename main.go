@@ -1,259 +1,191 @@
 package main
 
 import (
-	"errors"
-	"log"
+	"context"
+	"flag"
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
-	"strings"
+	"sync"
 	"syscall"
 	"time"
 
-	"github.com/PuerkitoBio/goquery"
 	ics "github.com/arran4/golang-ical"
-	"github.com/google/uuid"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+	"github.com/jforseth210/CarrollCalendarParser/carrollcal"
+	"github.com/jforseth210/CarrollCalendarParser/googlecal"
 )
 
-// Author: Justin Forseth 
-// Downloads html from Carroll College calendar and
-// parses it into an ics file 
+// Author: Justin Forseth
+// Downloads html from Carroll College calendar and parses it into an ics
+// file. Run `carrollcal filter` to narrow an existing ics file down to a
+// date range instead.
+
+const outputFile = "carroll.ics"
 
 func main() {
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "filter" {
+		runFilter(args[1:])
+		return
+	}
+	runScrape(args)
+}
+
+func runScrape(args []string) {
+	fs := flag.NewFlagSet("carrollcal", flag.ExitOnError)
+	concurrency := fs.Int("concurrency", 4, "number of event pages to fetch in parallel")
+	requestsPerSecond := fs.Float64("rate", 2, "max requests per second to send to carroll.edu")
+	userAgent := fs.String("user-agent", "", "User-Agent header to send with requests")
+	cacheDir := fs.String("cache", "", "directory to cache fetched pages in, enabling conditional GETs on re-runs")
+	incremental := fs.Bool("incremental", false, "reuse unchanged events from the previous carroll.ics instead of re-scraping everything; without -cache every event is re-scraped")
+	format := fs.String("format", "ics", "output format when not syncing to Google Calendar: ics, json, or csv")
+	googleCalendarID := fs.String("google-calendar-id", "", "sync scraped events into this Google Calendar ID instead of writing a file")
+	googleCredentials := fs.String("google-credentials", "", "path to OAuth/service-account credentials for -google-calendar-id")
+	fs.Parse(args)
+
 	// Validate argument length
-	if len(os.Args) != 3 {
+	positional := fs.Args()
+	if len(positional) != 2 {
 		println("Please enter the start and end months in the format YYYY-MM")
 		os.Exit(1)
 	}
 
-	start := os.Args[1]
-	end := os.Args[2]
-	// Make a list of month strings
-	months, err := generateMonthList(start, end)
-
+	start, err := time.Parse("2006-01", positional[0])
 	if err != nil {
 		println("Please enter the start and end months in the format YYYY-MM")
 		os.Exit(1)
 	}
-
-	// Set up a calendar
-	cal := ics.NewCalendar()
-	cal.SetMethod(ics.MethodRequest)
-
-	// Save calendar if process is interrupted
-	c := make(chan os.Signal)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-c
-		// Don't overwrite an existing complete calendar if it exists
-		os.WriteFile("carroll.ics.part", []byte(cal.Serialize()), 0655)
-		println("Program interrupted, saved incomplete calendar as carroll.ics.part")
+	end, err := time.Parse("2006-01", positional[1])
+	if err != nil {
+		println("Please enter the start and end months in the format YYYY-MM")
 		os.Exit(1)
-	}()
-
-	for _, month := range months {
-		// Load the month's calendar page
-		doc, err := loadPage("http://www.carroll.edu/news-events/events/" + month)
-		if err != nil {
-			log.Fatal(err)
-		}
-		// Find all the links to events
-		links := getEventLinksFromHTML(doc)
-
-		for _, link := range links {
-			println("Loading " + link)
-			// Load the event page
-			doc, err := loadPage(link)
-			if err != nil {
-				log.Println("Error loading " + link)
-				continue
-			}
-
-			// Find the title of the event
-			title := parseTitle(doc)
-
-			// Find the start time of the event
-			startTime, err := parseStartTime(doc)
-			if err != nil {
-				log.Println("Failed to find a start time for " + title)
-				continue
-			}
-
-			// Find the end time of the event
-			endTime, err := parseEndTime(doc)
-			if err != nil {
-				log.Println("Failed to find an end time for " + title)
-				continue
-			}
-
-			// Find the event location
-			location := parseLocation(doc)
-
-			// Find the event description
-			description := parseDescription(doc)
-
-			// Create an ics.VEvent with the data provided
-			addEvent(cal, title, *startTime, *endTime, location, description, link)
-		}
 	}
 
-	// Write out all the events to a file
-	os.WriteFile("carroll.ics", []byte(cal.Serialize()), 0755)
-}
-
-// Loads a page from a URL and parses the HTML
-func loadPage(url string) (*goquery.Document, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
+	scraper := &carrollcal.Scraper{
+		Fetcher: carrollcal.NewFetcher(http.DefaultClient, *concurrency, *requestsPerSecond, *userAgent),
+	}
+	if *cacheDir != "" {
+		scraper.Cache = &carrollcal.Cache{Dir: *cacheDir}
 	}
 
-	return goquery.NewDocumentFromReader(resp.Body)
-}
-
-// Get the links to events from a Carroll calendar page
-func getEventLinksFromHTML(doc *goquery.Document) []string {
-	// Find all the anchor tags in the month table
-	anchorTags := doc.Find("table a")
-
-	var links []string
+	// Track the calendar built so far so it can be saved if the process is
+	// interrupted, same as the pre-refactor carroll.ics.part behavior.
+	var (
+		partialMu  sync.Mutex
+		partialCal *ics.Calendar
+	)
+	scraper.OnProgress = func(cal *ics.Calendar) {
+		partialMu.Lock()
+		partialCal = cal
+		partialMu.Unlock()
+	}
 
-	// Iterate through the event links
-	anchorTags.Each(func(i int, anchorTag *goquery.Selection) {
-		// Get the href attribute of the anchor tag
-		relativeLink, exists := anchorTag.Attr("href")
-		if !exists {
-			return
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupt
+		partialMu.Lock()
+		cal := partialCal
+		partialMu.Unlock()
+
+		if cal == nil {
+			println("Program interrupted before any month finished scraping, nothing to save")
+			os.Exit(1)
 		}
 
-		// Make sure it's a link to a carroll event
-		if !strings.Contains(relativeLink, "/news-events/events") {
-			return
-		}
+		partFile := outputFile + ".part"
+		os.WriteFile(partFile, []byte(cal.Serialize()), 0644)
+		println("Program interrupted, saved incomplete calendar as " + partFile)
+		os.Exit(1)
+	}()
 
-		// Make sure it hasn't been added already
-		// (There are multiple links to multi-day events)
-		for _, existingLink := range links {
-			if existingLink == "https://www.carroll.edu"+relativeLink {
-				return
-			}
-		}
+	var calendar *ics.Calendar
+	if *incremental {
+		calendar, err = scraper.ScrapeRangeIncremental(start, end, loadPreviousCalendar())
+	} else {
+		calendar, err = scraper.ScrapeRange(start, end)
+	}
+	if err != nil {
+		println(err.Error())
+		os.Exit(1)
+	}
 
-		// Add the link to the list
-		links = append(links, "https://www.carroll.edu"+relativeLink)
-	})
-	// Return the list of links
-	return links
-}
+	if *googleCalendarID != "" {
+		syncToGoogleCalendar(calendar, start, end, *googleCalendarID, *googleCredentials)
+		return
+	}
 
-// Get a title from from a Carroll event page
-func parseTitle(doc *goquery.Document) string {
-	// Get the title
-	title := doc.Find(".hero__title").Text()
-	// Get rid of all caps
-	title = cases.Title(language.English).String(title)
-	// Get rid of extra whitespace
-	title = strings.TrimSpace(title)
-	return title
+	writeCalendar(calendar, *format)
 }
 
-// Get the start time from a Carroll event page
-func parseStartTime(doc *goquery.Document) (*time.Time, error) {
-	// Find the date area
-	dates := doc.Find(".event__date").Find("time")
-
-	// Find the start date
-	var unixString string
-	var exists bool
-	dates.Each(func(i int, date *goquery.Selection) {
-		if i == 0 {
-			unixString, exists = date.Attr("datetime")
-		}
-	})
-	// Parse
-	if !exists {
-		return nil, errors.New("No start time found")
-	}
-	dateInt, err := strconv.ParseInt(unixString, 10, 64)
+// writeCalendar encodes calendar in format and writes it to the file that
+// format maps to.
+func writeCalendar(calendar *ics.Calendar, format string) {
+	encoder, err := carrollcal.EncoderForFormat(format)
 	if err != nil {
-		return nil, err
+		println(err.Error())
+		os.Exit(1)
 	}
-	tm := time.Unix(dateInt, 0).Local()
-	return &tm, nil
-}
-func parseEndTime(doc *goquery.Document) (*time.Time, error) {
-	// Find the date area
-	dates := doc.Find(".event__date").Find("time")
-
-	//Find the end date
-	var unixString string
-	var exists bool
-	dates.Each(func(i int, date *goquery.Selection) {
-		unixString, exists = date.Attr("datetime")
-	})
 
-	if !exists {
-		return nil, errors.New("No end time found")
-	}
-	dateInt, err := strconv.ParseInt(unixString, 10, 64)
+	f, err := os.Create(outputFileForFormat(format))
 	if err != nil {
-		return nil, err
+		println(err.Error())
+		os.Exit(1)
 	}
-	tm := time.Unix(dateInt, 0).Local()
-	return &tm, nil
-}
-func parseLocation(doc *goquery.Document) string {
-	location := doc.Find(".event__location").Text()
-	location = strings.Replace(location, "Campus", "Campus\n", -1)
-	location = strings.TrimSpace(location)
-	return location
-}
-func parseDescription(doc *goquery.Document) string {
-	description := doc.Find(".text-content").Children().First().Text()
-	description = strings.TrimSpace(description)
-	return description
+	defer f.Close()
 
+	if err := encoder.Encode(f, calendar); err != nil {
+		println(err.Error())
+		os.Exit(1)
+	}
 }
 
-// Create an ics.VEvent with the data provided
-func addEvent(cal *ics.Calendar, summary string, start time.Time, end time.Time, location string, description string, url string) *ics.VEvent {
-	event := cal.AddEvent(uuid.NewString())
-	event.SetCreatedTime(time.Now())
-	event.SetDtStampTime(time.Now())
-	event.SetModifiedAt(time.Now())
-	event.SetStartAt(start)
-	event.SetEndAt(end)
-	event.SetSummary(summary)
-	event.SetLocation(location)
-	event.SetDescription(description)
-	event.SetURL(url)
-	return event
+// outputFileForFormat returns the file scraped events are written to for a
+// given -format.
+func outputFileForFormat(format string) string {
+	switch format {
+	case "json":
+		return "carroll.json"
+	case "csv":
+		return "carroll.csv"
+	default:
+		return outputFile
+	}
 }
 
-// This function generates a list of month strings from the start and end dates
-// It's AI generated and works by adding a month to the start month until it's
-// greater than the end date.
-func generateMonthList(start, end string) ([]string, error) {
-	var monthList []string
+// syncToGoogleCalendar diff-syncs calendar's events into the Google
+// Calendar identified by calendarID, authenticating with credentialsFile.
+// start and end are the YYYY-MM month arguments scraping was bounded by;
+// end is the first of the end month, but ScrapeRange scrapes through the
+// end of that month, so the sync window has to extend one month past end
+// to cover the same events.
+func syncToGoogleCalendar(calendar *ics.Calendar, start, end time.Time, calendarID, credentialsFile string) {
+	ctx := context.Background()
 
-	// Convert start and end dates to time.Time
-	startDate, err := time.Parse("2006-01", start)
+	syncer, err := googlecal.NewSyncer(ctx, credentialsFile, calendarID)
 	if err != nil {
-		return nil, err
+		println(err.Error())
+		os.Exit(1)
 	}
-	endDate, err := time.Parse("2006-01", end)
-	if err != nil {
-		return nil, err
+
+	if err := syncer.Sync(ctx, carrollcal.Events(calendar), start, end.AddDate(0, 1, 0)); err != nil {
+		println(err.Error())
+		os.Exit(1)
 	}
+}
 
-	// Generate month list
-	for !startDate.After(endDate) {
-		monthList = append(monthList, startDate.Format("200601"))
-		startDate = startDate.AddDate(0, 1, 0)
+// loadPreviousCalendar loads the carroll.ics written by a previous run, or
+// returns nil if there isn't one yet.
+func loadPreviousCalendar() *ics.Calendar {
+	f, err := os.Open(outputFile)
+	if err != nil {
+		return nil
 	}
+	defer f.Close()
 
-	return monthList, nil
+	previous, err := ics.ParseCalendar(f)
+	if err != nil {
+		return nil
+	}
+	return previous
 }